@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// damageExpression is a parsed damage characteristic such as "2", "D3", or
+// "D6+1": an optional dice pool plus a flat bonus.
+type damageExpression struct {
+	Dice  int
+	Sides int
+	Bonus int
+}
+
+// parseDamageExpression parses a damage characteristic in the usual
+// tabletop notation: a flat number ("2"), a dice pool ("D3", "2D6"), or a
+// dice pool plus a flat bonus ("D6+1"). An empty expression defaults to a
+// flat 1 damage.
+func parseDamageExpression(expr string) (damageExpression, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return damageExpression{Bonus: 1}, nil
+	}
+
+	upper := strings.ToUpper(expr)
+	dIndex := strings.Index(upper, "D")
+	if dIndex < 0 {
+		flat, err := strconv.Atoi(expr)
+		if err != nil {
+			return damageExpression{}, fmt.Errorf("invalid damage expression %q: %w", expr, err)
+		}
+		return damageExpression{Bonus: flat}, nil
+	}
+
+	dice := 1
+	if dIndex > 0 {
+		n, err := strconv.Atoi(upper[:dIndex])
+		if err != nil {
+			return damageExpression{}, fmt.Errorf("invalid damage expression %q: %w", expr, err)
+		}
+		dice = n
+	}
+	if dice < 0 {
+		return damageExpression{}, fmt.Errorf("invalid damage expression %q: dice count must not be negative", expr)
+	}
+
+	rest := upper[dIndex+1:]
+	sidesPart := rest
+	bonus := 0
+	if signIndex := strings.IndexAny(rest, "+-"); signIndex >= 0 {
+		sidesPart = rest[:signIndex]
+		b, err := strconv.Atoi(rest[signIndex:])
+		if err != nil {
+			return damageExpression{}, fmt.Errorf("invalid damage expression %q: %w", expr, err)
+		}
+		bonus = b
+	}
+
+	sides, err := strconv.Atoi(sidesPart)
+	if err != nil {
+		return damageExpression{}, fmt.Errorf("invalid damage expression %q: %w", expr, err)
+	}
+	if sides < 1 {
+		return damageExpression{}, fmt.Errorf("invalid damage expression %q: dice must have at least 1 side", expr)
+	}
+
+	return damageExpression{Dice: dice, Sides: sides, Bonus: bonus}, nil
+}
+
+// roll evaluates the expression once, rolling its dice pool (if any) and
+// adding the flat bonus.
+func (d damageExpression) roll(roller Roller) int {
+	total := d.Bonus
+
+	for _, r := range roller.RollN(d.Dice, d.Sides) {
+		total += r
+	}
+
+	return total
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	mathrand "math/rand/v2"
+)
+
+// rerollMode controls how a dice pool is re-rolled before successes are
+// counted.
+type rerollMode string
+
+const (
+	rerollNone rerollMode = "none"
+	rerollOnes rerollMode = "ones"
+	rerollAll  rerollMode = "all"
+)
+
+// Roller is the dice source the simulation pipeline rolls against. Threading
+// it as an argument instead of calling a package-scoped RNG is what makes a
+// run reproducible (PCGRoller with a fixed seed) or swappable for an
+// unbiased source (CryptoRoller).
+type Roller interface {
+	RollD(sides int) int
+	RollN(n, sides int) []int
+}
+
+// PCGRoller is a Roller backed by math/rand/v2's PCG, seeded so a run can be
+// replayed exactly.
+type PCGRoller struct {
+	seed uint64
+	rng  *mathrand.Rand
+}
+
+// NewPCGRoller seeds a PCGRoller from a single uint64; the same seed always
+// produces the same sequence of rolls.
+func NewPCGRoller(seed uint64) *PCGRoller {
+	return &PCGRoller{seed: seed, rng: mathrand.New(mathrand.NewPCG(seed, seed))}
+}
+
+func (p *PCGRoller) RollD(sides int) int {
+	return p.rng.IntN(sides) + 1
+}
+
+func (p *PCGRoller) RollN(n, sides int) []int {
+	results := make([]int, n)
+	for i := range results {
+		results[i] = p.RollD(sides)
+	}
+	return results
+}
+
+// CryptoRoller is a Roller backed by crypto/rand, for callers who want
+// unbiased dice over a reproducible sequence.
+type CryptoRoller struct{}
+
+func (CryptoRoller) RollD(sides int) int {
+	return int(cryptoUint32n(uint32(sides))) + 1
+}
+
+func (c CryptoRoller) RollN(n, sides int) []int {
+	results := make([]int, n)
+	for i := range results {
+		results[i] = c.RollD(sides)
+	}
+	return results
+}
+
+// cryptoUint32n returns a uniform random value in [0, n) read from
+// crypto/rand.
+func cryptoUint32n(n uint32) uint32 {
+	// Values below threshold (2^32 mod n of them) would make v % n favour
+	// small results, so they are redrawn.
+	threshold := -n % n
+	var buf [4]byte
+	for {
+		if _, err := rand.Read(buf[:]); err != nil {
+			panic(err)
+		}
+		if v := binary.LittleEndian.Uint32(buf[:]); v >= threshold {
+			return v % n
+		}
+	}
+}
+
+// randomSeed draws a seed for PCGRoller from crypto/rand, used when the
+// caller wants fast, non-reproducible dice and hasn't supplied their own
+// seed.
+func randomSeed() uint64 {
+	var buf [8]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		panic(err)
+	}
+	return binary.LittleEndian.Uint64(buf[:])
+}
+
+// newRoller builds the Roller a request asked for: "crypto" for an unbiased
+// source, or "pcg" (the default) seeded from the request's seed field, or
+// from crypto/rand if none was given.
+func newRoller(rollerKind string, seed *uint64) Roller {
+	if rollerKind == "crypto" {
+		return CryptoRoller{}
+	}
+
+	if seed != nil {
+		return NewPCGRoller(*seed)
+	}
+
+	return NewPCGRoller(randomSeed())
+}
+
+// forWorker returns an independent Roller for worker index i, so each
+// goroutine in a worker pool owns its own RNG state instead of contending on
+// a shared one. A PCGRoller is forked into a new, deterministically derived
+// seed; a CryptoRoller is stateless and safe to share as-is.
+func forWorker(roller Roller, i int) Roller {
+	if pcg, ok := roller.(*PCGRoller); ok {
+		return NewPCGRoller(pcg.seed ^ uint64(i)*0x9E3779B97F4A7C15)
+	}
+	return roller
+}
+
+// applyReroll re-rolls the dice that qualify for the given mode against
+// threshold: "ones" re-rolls any 1, "all" re-rolls any result that missed
+// threshold, "none" leaves the pool untouched.
+func applyReroll(results []int, mode rerollMode, threshold int, roller Roller) []int {
+	for i, result := range results {
+		switch mode {
+		case rerollOnes:
+			if result == 1 {
+				results[i] = roller.RollD(6)
+			}
+		case rerollAll:
+			if result < threshold {
+				results[i] = roller.RollD(6)
+			}
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,14 @@
+package handler
+
+import "testing"
+
+func TestPCGRollerRollDStaysInRange(t *testing.T) {
+	roller := NewPCGRoller(42)
+
+	for i := 0; i < 10000; i++ {
+		got := roller.RollD(6)
+		if got < 1 || got > 6 {
+			t.Fatalf("RollD(6) = %d, want a value in [1, 6]", got)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/render"
+	"github.com/go-playground/validator/v10"
+)
+
+func errSimulationNotFound(id string) error {
+	return fmt.Errorf("no simulation run with id %q", id)
+}
+
+// errResponse is a structured error payload: {error, code, field}. It
+// implements render.Renderer so it can be passed straight to render.Render.
+type errResponse struct {
+	Err        error  `json:"-"`
+	HTTPStatus int    `json:"-"`
+	Code       string `json:"code"`
+	Error      string `json:"error"`
+	Field      string `json:"field,omitempty"`
+}
+
+func (e *errResponse) Render(w http.ResponseWriter, r *http.Request) error {
+	render.Status(r, e.HTTPStatus)
+	return nil
+}
+
+// errInvalidRequest turns a decode or validation error into a structured
+// response. validator.ValidationErrors is unwrapped into a per-field code so
+// clients can react to "field X failed rule Y" without parsing prose.
+func errInvalidRequest(err error) render.Renderer {
+	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		fieldError := validationErrors[0]
+		return &errResponse{
+			Err:        err,
+			HTTPStatus: http.StatusUnprocessableEntity,
+			Code:       fieldError.Tag(),
+			Error:      validationMessage(fieldError),
+			Field:      fieldError.Field(),
+		}
+	}
+
+	return &errResponse{
+		Err:        err,
+		HTTPStatus: http.StatusBadRequest,
+		Code:       "invalid_request",
+		Error:      err.Error(),
+	}
+}
+
+func errNotFound(err error) render.Renderer {
+	return &errResponse{
+		Err:        err,
+		HTTPStatus: http.StatusNotFound,
+		Code:       "not_found",
+		Error:      err.Error(),
+	}
+}
+
+func validationMessage(fieldError validator.FieldError) string {
+	switch fieldError.Tag() {
+	case "required":
+		return "field '" + fieldError.Field() + "' is required"
+	case "min":
+		return "field '" + fieldError.Field() + "' must be at least " + fieldError.Param()
+	case "max":
+		return "field '" + fieldError.Field() + "' must be at most " + fieldError.Param()
+	case "oneof":
+		return "field '" + fieldError.Field() + "' must be one of: " + fieldError.Param()
+	default:
+		return "field '" + fieldError.Field() + "' failed validation: " + fieldError.Tag()
+	}
+}
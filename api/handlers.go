@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+)
+
+func handleSimulate(w http.ResponseWriter, r *http.Request) {
+	var body requestBody
+	if err := render.Decode(r, &body); err != nil {
+		render.Render(w, r, errInvalidRequest(err))
+		return
+	}
+
+	p, err := paramsFromBody(body)
+	if err != nil {
+		render.Render(w, r, errInvalidRequest(err))
+		return
+	}
+
+	if p.RunMode != "exact" && strings.Contains(r.Header.Get("Accept"), "application/x-ndjson") {
+		streamSimulate(w, r, p)
+		return
+	}
+
+	result := simulate(r.Context(), p)
+	if r.Context().Err() != nil {
+		// The deadline passed (or the client went away) mid-run: write
+		// nothing and let middleware.Timeout's own response stand.
+		return
+	}
+
+	respondSimulation(w, r, store.save(result))
+}
+
+func handleGetSimulation(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	data, ok := store.get(id)
+	if !ok {
+		render.Render(w, r, errNotFound(errSimulationNotFound(id)))
+		return
+	}
+
+	respondSimulation(w, r, data)
+}
+
+func handleCompare(w http.ResponseWriter, r *http.Request) {
+	var body compareRequestBody
+	if err := render.Decode(r, &body); err != nil {
+		render.Render(w, r, errInvalidRequest(err))
+		return
+	}
+
+	if err := validate.Struct(body); err != nil {
+		render.Render(w, r, errInvalidRequest(err))
+		return
+	}
+
+	entries := make([]comparisonEntry, len(body.Loadouts))
+	for i, loadout := range body.Loadouts {
+		p, err := paramsFromBody(loadout)
+		if err != nil {
+			render.Render(w, r, errInvalidRequest(err))
+			return
+		}
+
+		entries[i] = comparisonEntry{
+			Label: loadout.Label,
+			Data:  store.save(simulate(r.Context(), p)),
+		}
+
+		if r.Context().Err() != nil {
+			// Same deadline-passed handling as handleSimulate: stop
+			// comparing loadouts and write nothing further.
+			return
+		}
+	}
+
+	render.JSON(w, r, apiResponse{Success: true, Data: entries})
+}
+
+func handleHealthz(w http.ResponseWriter, r *http.Request) {
+	render.JSON(w, r, apiResponse{Success: true, Data: map[string]string{"status": "ok"}})
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = validator.New()
+
+// paramsFromBody validates body and turns it into the params the simulation
+// pipeline runs on, applying every field's default. For runMode "exact" it
+// also rejects fields exactDistribution can't model (rerolls, exploding
+// hits, mortal wounds, damage, wounds) instead of silently dropping them.
+func paramsFromBody(body requestBody) (params, error) {
+	if err := validate.Struct(body); err != nil {
+		return params{}, err
+	}
+
+	if _, err := parseDamageExpression(body.Damage); err != nil {
+		return params{}, err
+	}
+
+	hurtDifficulty := getDifficulty(body.Strength, body.Endurance)
+
+	runMode := body.RunMode
+	if runMode == "" {
+		runMode = "monte_carlo"
+	}
+
+	rerollTouches := rerollMode(body.RerollTouches)
+	if rerollTouches == "" {
+		rerollTouches = rerollNone
+	}
+	rerollHurts := rerollMode(body.RerollHurts)
+	if rerollHurts == "" {
+		rerollHurts = rerollNone
+	}
+
+	wounds := body.Wounds
+	if wounds == 0 {
+		wounds = 1
+	}
+
+	if runMode == "exact" {
+		if err := rejectExactModeExtras(body, rerollTouches, rerollHurts); err != nil {
+			return params{}, err
+		}
+	}
+
+	return params{
+		TouchDifficulty: body.TouchDifficulty,
+		HurtDifficulty:  hurtDifficulty,
+		ArmorSave:       body.ArmorSave,
+		InvuSave:        body.InvuSave,
+		RunNumber:       body.RunNumber,
+		DiceNumber:      body.DiceNumber,
+		RunMode:         runMode,
+
+		RerollTouches:   rerollTouches,
+		RerollHurts:     rerollHurts,
+		ExplodingHitsOn: body.ExplodingHitsOn,
+		MortalWoundsOn:  body.MortalWoundsOn,
+		ArmorPiercing:   body.ArmorPiercing,
+		Damage:          body.Damage,
+		Wounds:          wounds,
+
+		RollerKind: body.RollerKind,
+		Seed:       body.Seed,
+	}, nil
+}
+
+// rejectExactModeExtras reports an error naming every field set on body that
+// exactDistribution doesn't model. Without this, a request combining
+// runMode "exact" with e.g. rerollHurts would get back a distribution that
+// silently ignores the reroll instead of a response telling them so.
+func rejectExactModeExtras(body requestBody, rerollTouches, rerollHurts rerollMode) error {
+	var unsupported []string
+	if rerollTouches != rerollNone {
+		unsupported = append(unsupported, "rerollTouches")
+	}
+	if rerollHurts != rerollNone {
+		unsupported = append(unsupported, "rerollHurts")
+	}
+	if body.ExplodingHitsOn != 0 {
+		unsupported = append(unsupported, "explodingHitsOn")
+	}
+	if body.MortalWoundsOn != 0 {
+		unsupported = append(unsupported, "mortalWoundsOn")
+	}
+	if strings.TrimSpace(body.Damage) != "" {
+		unsupported = append(unsupported, "damage")
+	}
+	if body.Wounds != 0 {
+		unsupported = append(unsupported, "wounds")
+	}
+
+	if len(unsupported) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf(`runMode "exact" does not model %s; remove them or use runMode "monte_carlo"`, strings.Join(unsupported, ", "))
+}
+
+// simulate runs params through the pipeline (exact or Monte Carlo,
+// depending on RunMode) and summarizes the result. ctx is threaded down to
+// runAll so a request that outlives its deadline stops rolling instead of
+// running to completion after the caller has given up.
+func simulate(ctx context.Context, p params) simulationData {
+	if p.RunMode == "exact" {
+		return simulationData{Hurts: exactDistribution(ctx, p)}
+	}
+
+	roller := newRoller(p.RollerKind, p.Seed)
+	return summarizeRunResults(runAll(ctx, p, roller))
+}
+
+// summarizeRunResults turns raw per-trial results into the distributions
+// the API returns. Shared by the regular and NDJSON-streamed simulate
+// handlers.
+func summarizeRunResults(results []simulationResult) simulationData {
+	hurts := make([]int, len(results))
+	damage := make([]int, len(results))
+	modelsSlain := make([]int, len(results))
+	for i, result := range results {
+		hurts[i] = result.Hurts
+		damage[i] = result.Damage
+		modelsSlain[i] = result.ModelsSlain
+	}
+
+	damageDistribution := summarizeResults(damage)
+	modelsSlainDistribution := summarizeResults(modelsSlain)
+
+	return simulationData{
+		Hurts:       summarizeResults(hurts),
+		Damage:      &damageDistribution,
+		ModelsSlain: &modelsSlainDistribution,
+	}
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// ctxCheckInterval is how many trials a worker rolls between checks of
+// ctx.Done(), so cancellation is noticed promptly without paying for a
+// channel select on every single trial.
+const ctxCheckInterval = 256
+
+// simulationResult is the outcome of a single trial: how many hurts got
+// through, the total damage they (and any mortal wounds) dealt, and how
+// many models that damage slays.
+type simulationResult struct {
+	Hurts       int
+	Damage      int
+	ModelsSlain int
+}
+
+func getDifficulty(strength int, endurance int) int {
+	if strength >= endurance*2 {
+		return 2
+	} else if strength > endurance {
+		return 3
+	} else if strength*2 <= endurance {
+		return 6
+	} else if strength < endurance {
+		return 5
+	}
+
+	return 4
+}
+
+// touchStageResult is the outcome of rolling the to-touch pool.
+type touchStageResult struct {
+	Touches int
+}
+
+// rollTouchStage rolls numberOfDice to-touch dice, applying rerolls and
+// exploding hits. An exploding touch feeds one extra die back into the same
+// stage rather than bypassing it, so a run of 6s can keep exploding.
+func rollTouchStage(p params, numberOfDice int, roller Roller) touchStageResult {
+	touches := 0
+	pending := numberOfDice
+
+	for pending > 0 {
+		results := applyReroll(roller.RollN(pending, 6), p.RerollTouches, p.TouchDifficulty, roller)
+		pending = 0
+
+		for _, result := range results {
+			if result >= p.TouchDifficulty {
+				touches++
+			}
+			if p.ExplodingHitsOn > 0 && result >= p.ExplodingHitsOn {
+				pending++
+			}
+		}
+	}
+
+	return touchStageResult{Touches: touches}
+}
+
+// hurtStageResult is the outcome of rolling the to-hurt pool.
+type hurtStageResult struct {
+	Hurts        int
+	MortalWounds int
+}
+
+// rollHurtStage rolls numberOfDice to-hurt dice, applying rerolls. A roll
+// meeting MortalWoundsOn deals a mortal wound instead of an ordinary hurt,
+// bypassing the save stage entirely.
+func rollHurtStage(p params, numberOfDice int, roller Roller) hurtStageResult {
+	results := applyReroll(roller.RollN(numberOfDice, 6), p.RerollHurts, p.HurtDifficulty, roller)
+
+	result := hurtStageResult{}
+	for _, roll := range results {
+		if p.MortalWoundsOn > 0 && roll >= p.MortalWoundsOn {
+			result.MortalWounds++
+			continue
+		}
+		if roll >= p.HurtDifficulty {
+			result.Hurts++
+		}
+	}
+
+	return result
+}
+
+// rollSaveStage rolls armor then invulnerable saves against numberOfHurts
+// hurts, returning how many get through both. Armor piercing worsens the
+// armor save but never the invulnerable one.
+func rollSaveStage(p params, numberOfHurts int, roller Roller) int {
+	effectiveArmorSave := p.ArmorSave
+	if effectiveArmorSave >= 1 {
+		effectiveArmorSave += p.ArmorPiercing
+	}
+
+	afterArmorSave := 0
+	if effectiveArmorSave >= 1 {
+		for i := 0; i < numberOfHurts; i++ {
+			if roller.RollD(6) < effectiveArmorSave {
+				afterArmorSave++
+			}
+		}
+	} else {
+		afterArmorSave = numberOfHurts
+	}
+
+	afterInvuSave := 0
+	if p.InvuSave >= 1 {
+		for i := 0; i < afterArmorSave; i++ {
+			if roller.RollD(6) < p.InvuSave {
+				afterInvuSave++
+			}
+		}
+	} else {
+		afterInvuSave = afterArmorSave
+	}
+
+	return afterInvuSave
+}
+
+func runOnce(p params, roller Roller) simulationResult {
+	touchResult := rollTouchStage(p, p.DiceNumber, roller)
+	hurtResult := rollHurtStage(p, touchResult.Touches, roller)
+	finalHurts := rollSaveStage(p, hurtResult.Hurts, roller)
+
+	damage, err := parseDamageExpression(p.Damage)
+	if err != nil {
+		damage = damageExpression{Bonus: 1}
+	}
+
+	totalDamage := 0
+	for i := 0; i < finalHurts+hurtResult.MortalWounds; i++ {
+		totalDamage += damage.roll(roller)
+	}
+
+	modelsSlain := 0
+	if p.Wounds > 0 {
+		modelsSlain = totalDamage / p.Wounds
+	}
+
+	return simulationResult{
+		Hurts:       finalHurts + hurtResult.MortalWounds,
+		Damage:      totalDamage,
+		ModelsSlain: modelsSlain,
+	}
+}
+
+// runAll shards p.RunNumber trials across a pool of runtime.NumCPU()
+// workers, each with its own Roller, so a million-iteration run doesn't sit
+// on a single core or contend on shared RNG state. Each worker is assigned a
+// fixed, contiguous range of indices up front rather than pulling from a
+// shared queue, so which RNG stream produces which trial never depends on
+// goroutine scheduling: the same seed always reproduces the same run on a
+// machine with the same runtime.NumCPU(). A different CPU count changes the
+// ranges and worker seeds, and so the results.
+//
+// Workers stop rolling as soon as ctx is done, leaving the remainder of
+// their range as zero-valued results, so a request whose deadline has
+// passed (or whose client disconnected) doesn't keep spinning after nobody
+// is waiting on the answer. Each worker also recovers its own panics for the
+// same reason: a single bad trial leaves the rest of that worker's range
+// zero-valued instead of crashing every in-flight request.
+func runAll(ctx context.Context, p params, roller Roller) []simulationResult {
+	results := make([]simulationResult, p.RunNumber)
+
+	workers := runtime.NumCPU()
+	if workers > p.RunNumber {
+		workers = p.RunNumber
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	chunk := (p.RunNumber + workers - 1) / workers
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start := w * chunk
+		end := start + chunk
+		if end > p.RunNumber {
+			end = p.RunNumber
+		}
+		if start >= end {
+			continue
+		}
+
+		workerRoller := forWorker(roller, w)
+
+		wg.Add(1)
+		go func(start, end int, workerRoller Roller) {
+			defer wg.Done()
+			// middleware.Recoverer only guards the handler's own goroutine,
+			// not these worker goroutines: an unrecovered panic here (e.g.
+			// CryptoRoller hitting a crypto/rand.Read failure) would crash
+			// the whole process instead of just this request. Recovering
+			// and leaving the rest of this worker's range zero-valued keeps
+			// a single bad trial from taking down every in-flight request.
+			defer func() {
+				recover()
+			}()
+			for i := start; i < end; i++ {
+				if (i-start)%ctxCheckInterval == 0 && ctx.Err() != nil {
+					return
+				}
+				results[i] = runOnce(p, workerRoller)
+			}
+		}(start, end, workerRoller)
+	}
+
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestRunAllIsDeterministicForASeed(t *testing.T) {
+	p := params{
+		DiceNumber:      10,
+		TouchDifficulty: 4,
+		HurtDifficulty:  4,
+		ArmorSave:       3,
+		RunNumber:       200,
+		Wounds:          1,
+	}
+	const seed = 1234
+
+	first := runAll(context.Background(), p, NewPCGRoller(seed))
+	second := runAll(context.Background(), p, NewPCGRoller(seed))
+
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("runAll produced different results for the same seed:\n%+v\n%+v", first, second)
+	}
+}
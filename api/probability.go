@@ -0,0 +1,109 @@
+package handler
+
+import "context"
+
+// successProbability returns the probability that a single d6 roll meets or
+// beats target, i.e. P(roll >= target). A target of 1 or less always
+// succeeds; a target above 6 never does.
+func successProbability(target int) float64 {
+	if target <= 1 {
+		return 1
+	}
+	if target > 6 {
+		return 0
+	}
+	return float64(7-target) / 6
+}
+
+// failSaveProbability returns the probability that a save roll fails and the
+// hurt carries through to the next stage, mirroring the roll < save check in
+// rollSaveStage. A save of 0 or less means "no save", so everything carries
+// through; a save above 7 (possible once armor piercing worsens it past the
+// top of a d6) also always fails, since no roll can meet it.
+func failSaveProbability(save int) float64 {
+	if save <= 0 {
+		return 1
+	}
+	if save > 7 {
+		return 1
+	}
+	return float64(save-1) / 6
+}
+
+// binomialPMF returns the probability mass function of Binomial(n, p), i.e.
+// pmf[k] == P(X == k) for k in [0, n].
+func binomialPMF(n int, p float64) []float64 {
+	pmf := make([]float64, n+1)
+	pmf[0] = 1
+
+	for i := 0; i < n; i++ {
+		next := make([]float64, i+2)
+		for k := 0; k <= i; k++ {
+			if pmf[k] == 0 {
+				continue
+			}
+			next[k] += pmf[k] * (1 - p)
+			next[k+1] += pmf[k] * p
+		}
+		copy(pmf, next)
+	}
+
+	return pmf
+}
+
+// mixBinomial takes a distribution over a trial count n (dist[n] == P(N =
+// n)) and, for each n, spreads its weight across Binomial(n, p) outcomes,
+// returning the resulting marginal distribution over successes. This is how
+// one simulation stage (e.g. touches) feeds the next (e.g. hurts) without
+// ever sampling. It is the expensive part of exactDistribution (O(n^3) over
+// DiceNumber), so it bails out as soon as ctx is done, leaving whatever mass
+// it has accumulated so far rather than spinning past a timed-out or
+// disconnected request.
+func mixBinomial(ctx context.Context, dist []float64, p float64) []float64 {
+	maxN := len(dist) - 1
+	mixed := make([]float64, maxN+1)
+
+	for n, weight := range dist {
+		if ctx.Err() != nil {
+			return mixed
+		}
+		if weight == 0 {
+			continue
+		}
+		pmf := binomialPMF(n, p)
+		for k, pk := range pmf {
+			mixed[k] += weight * pk
+		}
+	}
+
+	return mixed
+}
+
+// exactDistribution computes the true probability distribution of final
+// hurts by convolving three binomial stages: touches, hurts, and saves
+// (armor then invu). DiceNumber is capped (see requestBody) to keep this
+// tractable, and each convolution stage also checks ctx so a request whose
+// deadline passes mid-computation stops instead of burning CPU with nobody
+// waiting on the answer. It models armor piercing (folded into the armor
+// stage's threshold, same as rollSaveStage), but rerolls, exploding hits,
+// mortal wounds and damage stay Monte Carlo only since they break the
+// binomial independence this convolution relies on; paramsFromBody rejects
+// exact-mode requests that set those fields rather than silently ignoring
+// them.
+func exactDistribution(ctx context.Context, p params) Distribution {
+	if ctx.Err() != nil {
+		return Distribution{}
+	}
+
+	effectiveArmorSave := p.ArmorSave
+	if effectiveArmorSave >= 1 {
+		effectiveArmorSave += p.ArmorPiercing
+	}
+
+	touches := binomialPMF(p.DiceNumber, successProbability(p.TouchDifficulty))
+	hurts := mixBinomial(ctx, touches, successProbability(p.HurtDifficulty))
+	afterArmor := mixBinomial(ctx, hurts, failSaveProbability(effectiveArmorSave))
+	afterInvu := mixBinomial(ctx, afterArmor, failSaveProbability(p.InvuSave))
+
+	return summarizeExact(afterInvu)
+}
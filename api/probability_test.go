@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestExactDistributionFrequenciesSumToOne(t *testing.T) {
+	cases := []params{
+		{DiceNumber: 10, TouchDifficulty: 4, HurtDifficulty: 4, ArmorSave: 3, InvuSave: 0},
+		{DiceNumber: 5, TouchDifficulty: 2, HurtDifficulty: 6, ArmorSave: 0, InvuSave: 4, ArmorPiercing: 2},
+		{DiceNumber: 1, TouchDifficulty: 6, HurtDifficulty: 2, ArmorSave: 6, InvuSave: 0},
+	}
+
+	for _, p := range cases {
+		dist := exactDistribution(context.Background(), p)
+
+		sum := 0.0
+		for _, bucket := range dist.Histogram {
+			sum += bucket.Frequency
+		}
+
+		if math.Abs(sum-1) > 1e-9 {
+			t.Errorf("exactDistribution(%+v) frequencies sum to %v, want 1", p, sum)
+		}
+	}
+}
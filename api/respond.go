@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/render"
+)
+
+// respondSimulation writes a simulation result in whichever format the
+// client asked for via its Accept header: text/csv for a histogram
+// spreadsheet, text/html for a quick human-readable summary, and JSON (the
+// default) for everything else.
+func respondSimulation(w http.ResponseWriter, r *http.Request, data simulationData) {
+	accept := r.Header.Get("Accept")
+
+	switch {
+	case strings.Contains(accept, "text/csv"):
+		writeHistogramCSV(w, data)
+	case strings.Contains(accept, "text/html"):
+		render.HTML(w, r, simulationHTML(data))
+	default:
+		render.JSON(w, r, apiResponse{Success: true, Data: data})
+	}
+}
+
+func writeHistogramCSV(w http.ResponseWriter, data simulationData) {
+	w.Header().Set("Content-Type", "text/csv")
+	fmt.Fprintln(w, "outcome,count,frequency")
+	for _, bucket := range data.Hurts.Histogram {
+		fmt.Fprintf(w, "%d,%d,%f\n", bucket.Outcome, bucket.Count, bucket.Frequency)
+	}
+}
+
+func simulationHTML(data simulationData) string {
+	return fmt.Sprintf(
+		"<h1>Simulation %s</h1><p>Mean hurts: %.2f (stddev %.2f, range %d-%d)</p>",
+		data.ID, data.Hurts.Mean, data.Hurts.StdDev, data.Hurts.Min, data.Hurts.Max,
+	)
+}
@@ -0,0 +1,143 @@
+package handler
+
+import "math"
+
+// Distribution is the statistical summary returned for a simulation, whether
+// it was obtained by sampling (Monte Carlo) or computed analytically
+// (exact).
+type Distribution struct {
+	Mean      float64           `json:"mean"`
+	StdDev    float64           `json:"stdDev"`
+	Min       int               `json:"min"`
+	Max       int               `json:"max"`
+	Median    float64           `json:"median"`
+	P10       float64           `json:"p10"`
+	P25       float64           `json:"p25"`
+	P75       float64           `json:"p75"`
+	P90       float64           `json:"p90"`
+	Histogram []HistogramBucket `json:"histogram"`
+	AtLeast   []ProbAtLeast     `json:"probAtLeast"`
+}
+
+// HistogramBucket is the count and frequency of a single outcome value
+// (final hurts, damage or models slain, depending on the Distribution)
+// across all runs. Count is only meaningful for sampled
+// distributions; exact distributions leave it at 0 and report Frequency
+// only.
+type HistogramBucket struct {
+	Outcome   int     `json:"outcome"`
+	Count     int     `json:"count"`
+	Frequency float64 `json:"frequency"`
+}
+
+// ProbAtLeast is P(outcome >= Hurts) for one outcome value. The field is
+// named for hurts but holds whichever quantity the Distribution summarizes.
+type ProbAtLeast struct {
+	Hurts       int     `json:"hurts"`
+	Probability float64 `json:"probability"`
+}
+
+// summarizeResults builds a Distribution from raw Monte Carlo outcomes.
+func summarizeResults(results []int) Distribution {
+	if len(results) == 0 {
+		return Distribution{}
+	}
+
+	maxOutcome := results[0]
+	for _, r := range results {
+		if r > maxOutcome {
+			maxOutcome = r
+		}
+	}
+
+	counts := make([]int, maxOutcome+1)
+	for _, r := range results {
+		counts[r]++
+	}
+
+	pmf := make([]float64, len(counts))
+	for k, c := range counts {
+		pmf[k] = float64(c) / float64(len(results))
+	}
+
+	dist := distributionFromPMF(pmf)
+	for k, c := range counts {
+		dist.Histogram[k].Count = c
+	}
+
+	return dist
+}
+
+// summarizeExact builds a Distribution straight from a probability mass
+// function, indexed by outcome (pmf[k] == P(finalHurts == k)), without ever
+// sampling.
+func summarizeExact(pmf []float64) Distribution {
+	return distributionFromPMF(pmf)
+}
+
+// distributionFromPMF computes every statistic in a Distribution from a
+// probability mass function indexed by outcome.
+func distributionFromPMF(pmf []float64) Distribution {
+	if len(pmf) == 0 {
+		return Distribution{}
+	}
+
+	var mean, variance float64
+	for k, p := range pmf {
+		mean += float64(k) * p
+	}
+	for k, p := range pmf {
+		diff := float64(k) - mean
+		variance += diff * diff * p
+	}
+
+	histogram := make([]HistogramBucket, len(pmf))
+	atLeast := make([]ProbAtLeast, len(pmf))
+	tail := 0.0
+	for k := len(pmf) - 1; k >= 0; k-- {
+		tail += pmf[k]
+		atLeast[k] = ProbAtLeast{Hurts: k, Probability: tail}
+		histogram[k] = HistogramBucket{Outcome: k, Frequency: pmf[k]}
+	}
+
+	minOutcome, maxOutcome := 0, len(pmf)-1
+	for k, p := range pmf {
+		if p > 0 {
+			minOutcome = k
+			break
+		}
+	}
+	for k := len(pmf) - 1; k >= 0; k-- {
+		if pmf[k] > 0 {
+			maxOutcome = k
+			break
+		}
+	}
+
+	return Distribution{
+		Mean:      mean,
+		StdDev:    math.Sqrt(variance),
+		Min:       minOutcome,
+		Max:       maxOutcome,
+		Median:    quantile(pmf, 0.5),
+		P10:       quantile(pmf, 0.10),
+		P25:       quantile(pmf, 0.25),
+		P75:       quantile(pmf, 0.75),
+		P90:       quantile(pmf, 0.90),
+		Histogram: histogram,
+		AtLeast:   atLeast,
+	}
+}
+
+// quantile returns the smallest k such that P(X <= k) >= q, reading off the
+// PMF's cumulative distribution.
+func quantile(pmf []float64, q float64) float64 {
+	cumulative := 0.0
+	for k, p := range pmf {
+		cumulative += p
+		if cumulative >= q {
+			return float64(k)
+		}
+	}
+	return float64(len(pmf) - 1)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+)
+
+// runStore keeps completed simulation runs in memory so they can be fetched
+// again by id via GET /simulate/{id}. It is process-local: on a serverless
+// platform each instance has its own store, so lookups are best-effort
+// rather than durable.
+type runStore struct {
+	mu   sync.RWMutex
+	runs map[string]simulationData
+}
+
+var store = &runStore{runs: make(map[string]simulationData)}
+
+func (s *runStore) save(data simulationData) simulationData {
+	data.ID = newRunID()
+
+	s.mu.Lock()
+	s.runs[data.ID] = data
+	s.mu.Unlock()
+
+	return data
+}
+
+func (s *runStore) get(id string) (simulationData, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.runs[id]
+	return data, ok
+}
+
+func newRunID() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/render"
+)
+
+// progressUpdate is one line of NDJSON progress for a long streamed
+// simulation: how far along it is, the running mean, and a rough ETA.
+type progressUpdate struct {
+	Count      int     `json:"count"`
+	Total      int     `json:"total"`
+	MeanSoFar  float64 `json:"meanSoFar"`
+	ETASeconds float64 `json:"etaSeconds"`
+}
+
+// progressReportEvery controls how many trials run between NDJSON progress
+// lines; small enough to feel live, large enough not to dominate runtime
+// with flushes.
+const progressReportEvery = 1000
+
+// streamSimulate runs p.RunNumber trials on a single goroutine, writing an
+// NDJSON progress line every progressReportEvery trials and a final line
+// with the full result, so long Monte Carlo runs show progress instead of
+// blocking until completion. It falls back to a single buffered response if
+// the ResponseWriter can't be flushed incrementally.
+func streamSimulate(w http.ResponseWriter, r *http.Request, p params) {
+	ctx := r.Context()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		data := store.save(simulate(ctx, p))
+		render.JSON(w, r, apiResponse{Success: true, Data: data})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(w)
+	roller := newRoller(p.RollerKind, p.Seed)
+	results := make([]simulationResult, 0, p.RunNumber)
+
+	start := time.Now()
+	sumHurts := 0
+
+	for i := 1; i <= p.RunNumber; i++ {
+		if ctx.Err() != nil {
+			// The client is gone or the deadline passed: stop rolling, no
+			// one is reading the stream anymore.
+			return
+		}
+
+		result := runOnce(p, roller)
+		results = append(results, result)
+		sumHurts += result.Hurts
+
+		if i%progressReportEvery == 0 || i == p.RunNumber {
+			elapsed := time.Since(start).Seconds()
+			meanSoFar := float64(sumHurts) / float64(i)
+			etaSeconds := 0.0
+			if i < p.RunNumber {
+				etaSeconds = elapsed / float64(i) * float64(p.RunNumber-i)
+			}
+
+			encoder.Encode(progressUpdate{
+				Count:      i,
+				Total:      p.RunNumber,
+				MeanSoFar:  meanSoFar,
+				ETASeconds: etaSeconds,
+			})
+			flusher.Flush()
+		}
+	}
+
+	data := store.save(summarizeRunResults(results))
+	encoder.Encode(apiResponse{Success: true, Data: data})
+	flusher.Flush()
+}
@@ -0,0 +1,76 @@
+package handler
+
+type requestBody struct {
+	Label           string `json:"label" validate:"omitempty"`
+	Strength        int    `json:"strength" validate:"required,min=1"`
+	Endurance       int    `json:"endurance" validate:"required,min=1"`
+	DiceNumber      int    `json:"diceNumber" validate:"required,min=1,max=500"`
+	TouchDifficulty int    `json:"touchDifficulty" validate:"required,min=2,max=6"`
+	ArmorSave       int    `json:"armorSave" validate:"min=0,max=6"`
+	InvuSave        int    `json:"invuSave" validate:"min=0,max=6"`
+	RunNumber       int    `json:"runNumber" validate:"required,min=1,max=1000000"`
+	RunMode         string `json:"runMode" validate:"omitempty,oneof=monte_carlo exact"`
+
+	RerollTouches   string `json:"rerollTouches" validate:"omitempty,oneof=none ones all"`
+	RerollHurts     string `json:"rerollHurts" validate:"omitempty,oneof=none ones all"`
+	ExplodingHitsOn int    `json:"explodingHitsOn" validate:"omitempty,min=2,max=6"`
+	MortalWoundsOn  int    `json:"mortalWoundsOn" validate:"omitempty,min=2,max=6"`
+	ArmorPiercing   int    `json:"armorPiercing" validate:"omitempty,min=0,max=6"`
+	Damage          string `json:"damage" validate:"omitempty"`
+	Wounds          int    `json:"wounds" validate:"omitempty,min=1"`
+
+	RollerKind string  `json:"roller" validate:"omitempty,oneof=pcg crypto"`
+	Seed       *uint64 `json:"seed" validate:"omitempty"`
+}
+
+// compareRequestBody is the payload for POST /compare: two or more loadouts
+// to run side by side.
+type compareRequestBody struct {
+	Loadouts []requestBody `json:"loadouts" validate:"required,min=2,dive"`
+}
+
+type params struct {
+	DiceNumber      int
+	TouchDifficulty int
+	HurtDifficulty  int
+	ArmorSave       int
+	InvuSave        int
+	RunNumber       int
+	RunMode         string
+
+	RerollTouches   rerollMode
+	RerollHurts     rerollMode
+	ExplodingHitsOn int
+	MortalWoundsOn  int
+	ArmorPiercing   int
+	Damage          string
+	Wounds          int
+
+	RollerKind string
+	Seed       *uint64
+}
+
+// apiResponse is the envelope every JSON endpoint replies with on success.
+// Errors are written as an errResponse ({error, code, field}) instead.
+type apiResponse struct {
+	Success bool        `json:"success"`
+	Data    interface{} `json:"data,omitempty"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// simulationData is the payload returned for a simulation run: its storage
+// id, the distribution of final hurts, and, when the run was sampled rather
+// than computed exactly, the distributions of damage dealt and models
+// slain.
+type simulationData struct {
+	ID          string        `json:"id,omitempty"`
+	Hurts       Distribution  `json:"hurts"`
+	Damage      *Distribution `json:"damage,omitempty"`
+	ModelsSlain *Distribution `json:"modelsSlain,omitempty"`
+}
+
+// comparisonEntry is one loadout's result within a /compare response.
+type comparisonEntry struct {
+	Label string         `json:"label,omitempty"`
+	Data  simulationData `json:"data"`
+}